@@ -0,0 +1,292 @@
+// Package activitypub publishes a booksing instance's "new arrivals" as a
+// minimal ActivityPub actor, so Mastodon/Pleroma users can follow it and
+// see new acquisitions show up in their timeline.
+package activitypub
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/gnur/booksing"
+	"github.com/go-fed/httpsig"
+)
+
+const activityContext = "https://www.w3.org/ns/activitystreams"
+
+// maxActivities bounds how many Create activities Publisher keeps in
+// memory for the outbox, so a long-running instance doesn't grow it
+// without limit.
+const maxActivities = 200
+
+// KeyStore persists the actor's RSA keypair across restarts.
+type KeyStore interface {
+	GetActorKeyPair() (*KeyPair, error)
+	SaveActorKeyPair(*KeyPair) error
+}
+
+// Actor is the ActivityPub actor document served at /ap/actor.
+type Actor struct {
+	Context           string    `json:"@context"`
+	ID                string    `json:"id"`
+	Type              string    `json:"type"`
+	PreferredUsername string    `json:"preferredUsername"`
+	Name              string    `json:"name"`
+	Inbox             string    `json:"inbox"`
+	Outbox            string    `json:"outbox"`
+	PublicKey         publicKey `json:"publicKey"`
+}
+
+type publicKey struct {
+	ID           string `json:"id"`
+	Owner        string `json:"owner"`
+	PublicKeyPEM string `json:"publicKeyPem"`
+}
+
+// Note is a single "new arrival" announcement for one book.
+type Note struct {
+	Context   string `json:"@context"`
+	ID        string `json:"id"`
+	Type      string `json:"type"`
+	Published string `json:"published"`
+	AttrTo    string `json:"attributedTo"`
+	Content   string `json:"content"`
+	URL       string `json:"url"`
+}
+
+// Create wraps a Note in a Create activity for the outbox/delivery.
+type Create struct {
+	Context   string   `json:"@context"`
+	ID        string   `json:"id"`
+	Type      string   `json:"type"`
+	Actor     string   `json:"actor"`
+	Published string   `json:"published"`
+	To        []string `json:"to"`
+	Object    Note     `json:"object"`
+}
+
+// Outbox is an OrderedCollection of this instance's Create activities.
+type Outbox struct {
+	Context      string   `json:"@context"`
+	ID           string   `json:"id"`
+	Type         string   `json:"type"`
+	TotalItems   int      `json:"totalItems"`
+	OrderedItems []Create `json:"orderedItems"`
+}
+
+// WebFinger is the response to /.well-known/webfinger for acct:library@fqdn.
+type WebFinger struct {
+	Subject string          `json:"subject"`
+	Links   []webFingerLink `json:"links"`
+}
+
+type webFingerLink struct {
+	Rel  string `json:"rel"`
+	Type string `json:"type"`
+	Href string `json:"href"`
+}
+
+// Publisher builds and signs ActivityPub activities for a single booksing
+// instance actor, and delivers them to configured follower inboxes.
+type Publisher struct {
+	fqdn      string
+	keys      KeyStore
+	client    *http.Client
+	followers []string
+
+	mu         sync.Mutex
+	activities []Create
+}
+
+// NewPublisher loads (or generates and persists) the actor keypair and
+// returns a Publisher that delivers to the given follower inboxes.
+func NewPublisher(fqdn string, keys KeyStore, followers []string) (*Publisher, error) {
+	kp, err := keys.GetActorKeyPair()
+	if err != nil {
+		kp, err = GenerateKeyPair()
+		if err != nil {
+			return nil, err
+		}
+		if err := keys.SaveActorKeyPair(kp); err != nil {
+			return nil, fmt.Errorf("unable to persist actor keypair: %w", err)
+		}
+	}
+
+	return &Publisher{
+		fqdn:      fqdn,
+		keys:      keys,
+		client:    &http.Client{Timeout: 10 * time.Second},
+		followers: followers,
+	}, nil
+}
+
+// ActorID is the canonical id of this instance's actor.
+func (p *Publisher) ActorID() string {
+	return p.fqdn + "/ap/actor"
+}
+
+// Actor builds the actor document served at /ap/actor.
+func (p *Publisher) Actor() (*Actor, error) {
+	kp, err := p.keys.GetActorKeyPair()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Actor{
+		Context:           activityContext,
+		ID:                p.ActorID(),
+		Type:              "Service",
+		PreferredUsername: "library",
+		Name:              "booksing",
+		Inbox:             p.fqdn + "/ap/inbox",
+		Outbox:            p.fqdn + "/ap/outbox",
+		PublicKey: publicKey{
+			ID:           p.ActorID() + "#main-key",
+			Owner:        p.ActorID(),
+			PublicKeyPEM: kp.PublicKeyPEM,
+		},
+	}, nil
+}
+
+// WebFinger resolves acct:library@<host> to the actor document.
+func (p *Publisher) WebFinger() WebFinger {
+	return WebFinger{
+		Subject: "acct:library@" + hostOf(p.fqdn),
+		Links: []webFingerLink{
+			{Rel: "self", Type: "application/activity+json", Href: p.ActorID()},
+		},
+	}
+}
+
+// Outbox returns the OrderedCollection of activities published so far.
+func (p *Publisher) Outbox() Outbox {
+	p.mu.Lock()
+	items := make([]Create, len(p.activities))
+	copy(items, p.activities)
+	p.mu.Unlock()
+
+	return Outbox{
+		Context:      activityContext,
+		ID:           p.fqdn + "/ap/outbox",
+		Type:         "OrderedCollection",
+		TotalItems:   len(items),
+		OrderedItems: items,
+	}
+}
+
+// Announce builds a Create(Note) activity for a newly indexed book, records
+// it in the outbox, and delivers it to every configured follower inbox in
+// the background, retrying with exponential backoff on failure.
+func (p *Publisher) Announce(ctx context.Context, b booksing.Book) {
+	now := time.Now().UTC().Format(time.RFC3339)
+	create := Create{
+		Context:   activityContext,
+		ID:        fmt.Sprintf("%s/ap/books/%s/activity", p.fqdn, b.Hash),
+		Type:      "Create",
+		Actor:     p.ActorID(),
+		Published: now,
+		To:        []string{"https://www.w3.org/ns/activitystreams#Public"},
+		Object: Note{
+			Context:   activityContext,
+			ID:        fmt.Sprintf("%s/ap/books/%s", p.fqdn, b.Hash),
+			Type:      "Article",
+			Published: now,
+			AttrTo:    p.ActorID(),
+			Content:   fmt.Sprintf("New arrival: %s by %s", b.Title, b.Author),
+			URL:       fmt.Sprintf("%s/ap/books/%s", p.fqdn, b.Hash),
+		},
+	}
+
+	p.mu.Lock()
+	p.activities = append(p.activities, create)
+	if len(p.activities) > maxActivities {
+		p.activities = p.activities[len(p.activities)-maxActivities:]
+	}
+	p.mu.Unlock()
+
+	for _, inbox := range p.followers {
+		go p.deliverWithRetry(ctx, inbox, create)
+	}
+}
+
+func (p *Publisher) deliverWithRetry(ctx context.Context, inbox string, activity Create) {
+	backoff := time.Second
+	for attempt := 0; attempt < 5; attempt++ {
+		if err := p.deliver(ctx, inbox, activity); err == nil {
+			return
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+func (p *Publisher) deliver(ctx context.Context, inbox string, activity Create) error {
+	body, err := json.Marshal(activity)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, inbox, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/activity+json")
+	req.Header.Set("Date", time.Now().UTC().Format(http.TimeFormat))
+	req.Header.Set("Host", req.URL.Host)
+
+	if err := p.sign(req, body); err != nil {
+		return err
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("delivery to %s failed with status %d", inbox, resp.StatusCode)
+	}
+	return nil
+}
+
+func (p *Publisher) sign(req *http.Request, body []byte) error {
+	kp, err := p.keys.GetActorKeyPair()
+	if err != nil {
+		return err
+	}
+	key, err := kp.privateKey()
+	if err != nil {
+		return err
+	}
+
+	signer, _, err := httpsig.NewSigner(
+		[]httpsig.Algorithm{httpsig.RSA_SHA256},
+		httpsig.DigestSha256,
+		[]string{httpsig.RequestTarget, "host", "date", "digest"},
+		httpsig.Signature,
+		0,
+	)
+	if err != nil {
+		return fmt.Errorf("unable to build http signer: %w", err)
+	}
+
+	return signer.SignRequest(key, p.ActorID()+"#main-key", req, body)
+}
+
+func hostOf(fqdn string) string {
+	u, err := url.Parse(fqdn)
+	if err != nil || u.Host == "" {
+		return fqdn
+	}
+	return u.Host
+}
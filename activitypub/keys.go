@@ -0,0 +1,46 @@
+package activitypub
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+)
+
+// KeyPair is the actor's persistent RSA keypair, used to sign outgoing
+// deliveries so remote servers can verify they came from this instance.
+type KeyPair struct {
+	PublicKeyPEM  string
+	PrivateKeyPEM string
+}
+
+// GenerateKeyPair creates a new 2048-bit RSA keypair, PEM-encoded for storage.
+func GenerateKeyPair() (*KeyPair, error) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, fmt.Errorf("unable to generate actor keypair: %w", err)
+	}
+
+	privBytes := x509.MarshalPKCS1PrivateKey(key)
+	privPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: privBytes})
+
+	pubBytes, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("unable to marshal actor public key: %w", err)
+	}
+	pubPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubBytes})
+
+	return &KeyPair{
+		PublicKeyPEM:  string(pubPEM),
+		PrivateKeyPEM: string(privPEM),
+	}, nil
+}
+
+func (k *KeyPair) privateKey() (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode([]byte(k.PrivateKeyPEM))
+	if block == nil {
+		return nil, fmt.Errorf("invalid private key PEM")
+	}
+	return x509.ParsePKCS1PrivateKey(block.Bytes)
+}
@@ -0,0 +1,21 @@
+package booksing
+
+import "time"
+
+// Book is a single parsed, indexed book. It doubles as the Meili document
+// shape, so field names here are also Meili's searchable attributes. The
+// sqlitefts index stores only Title, Author, Description, Hash and Added.
+type Book struct {
+	Hash          string    `json:"Hash" storm:"id"`
+	Title         string    `json:"Title"`
+	Author        string    `json:"Author"`
+	Description   string    `json:"Description"`
+	ISBN          string    `json:"ISBN,omitempty"`
+	Cover         string    `json:"Cover,omitempty"`
+	PublishedDate string    `json:"PublishedDate,omitempty"`
+	Categories    string    `json:"Categories,omitempty"`
+	PageCount     int       `json:"PageCount,omitempty"`
+	Path          string    `json:"Path"`
+	Size          int64     `json:"Size"`
+	Added         time.Time `json:"Added"`
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gnur/booksing/activitypub"
+)
+
+func (app *booksingApp) apActor(c *gin.Context) {
+	actor, err := app.activityPub.Actor()
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+	c.Header("Content-Type", "application/activity+json")
+	c.JSON(http.StatusOK, actor)
+}
+
+func (app *booksingApp) apOutbox(c *gin.Context) {
+	c.Header("Content-Type", "application/activity+json")
+	c.JSON(http.StatusOK, app.activityPub.Outbox())
+}
+
+// apBook serves the Article object for a single book, as referenced by the
+// Note objects announced in the outbox.
+func (app *booksingApp) apBook(c *gin.Context) {
+	hash := c.Param("hash")
+	b, err := app.s.GetBook(hash)
+	if err != nil || b == nil {
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+
+	c.Header("Content-Type", "application/activity+json")
+	c.JSON(http.StatusOK, activitypub.Note{
+		Context:   "https://www.w3.org/ns/activitystreams",
+		ID:        fmt.Sprintf("%s/ap/books/%s", app.cfg.FQDN, b.Hash),
+		Type:      "Article",
+		Published: b.Added.UTC().Format(time.RFC3339),
+		AttrTo:    app.activityPub.ActorID(),
+		Content:   fmt.Sprintf("New arrival: %s by %s", b.Title, b.Author),
+		URL:       fmt.Sprintf("%s/ap/books/%s", app.cfg.FQDN, b.Hash),
+	})
+}
+
+// activityPubLoop announces every newly indexed book as a Create activity,
+// delivering it to configured follower inboxes.
+func (app *booksingApp) activityPubLoop() {
+	for b := range app.apQ {
+		app.activityPub.Announce(context.Background(), b)
+	}
+}
+
+// webfinger resolves acct:library@fqdn to the instance actor, as required
+// for Mastodon/Pleroma to discover it from a user-entered handle.
+func (app *booksingApp) webfinger(c *gin.Context) {
+	resource := c.Query("resource")
+	wf := app.activityPub.WebFinger()
+	if resource != "" && resource != wf.Subject {
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+
+	c.Header("Content-Type", "application/jrd+json")
+	c.JSON(http.StatusOK, wf)
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	maxLoginAttempts   = 5
+	loginAttemptWindow = 15 * time.Minute
+)
+
+var errTooManyAttempts = errors.New("too many failed login attempts, try again later")
+
+type loginAttempt struct {
+	count    int
+	firstTry time.Time
+}
+
+// loginLimiter rate limits failed password attempts per IP+username, so a
+// brute force of one account from one source IP gets locked out without
+// needing a distributed store.
+type loginLimiter struct {
+	attempts sync.Map // key: ip+"/"+username -> *loginAttempt
+}
+
+// newLoginLimiter starts a loginLimiter along with a background sweep that
+// drops expired entries, so an attacker cycling through usernames/IPs
+// can't grow attempts without bound.
+func newLoginLimiter() *loginLimiter {
+	l := &loginLimiter{}
+	go l.sweepLoop()
+	return l
+}
+
+func (l *loginLimiter) sweepLoop() {
+	ticker := time.NewTicker(loginAttemptWindow)
+	defer ticker.Stop()
+	for range ticker.C {
+		l.sweep()
+	}
+}
+
+func (l *loginLimiter) sweep() {
+	now := time.Now()
+	l.attempts.Range(func(key, value interface{}) bool {
+		a := value.(*loginAttempt)
+		if now.Sub(a.firstTry) > loginAttemptWindow {
+			l.attempts.Delete(key)
+		}
+		return true
+	})
+}
+
+func (l *loginLimiter) blocked(key string) bool {
+	v, ok := l.attempts.Load(key)
+	if !ok {
+		return false
+	}
+	a := v.(*loginAttempt)
+	if time.Since(a.firstTry) > loginAttemptWindow {
+		l.attempts.Delete(key)
+		return false
+	}
+	return a.count >= maxLoginAttempts
+}
+
+func (l *loginLimiter) recordFailure(key string) {
+	v, _ := l.attempts.LoadOrStore(key, &loginAttempt{firstTry: time.Now()})
+	a := v.(*loginAttempt)
+	a.count++
+}
+
+func (l *loginLimiter) reset(key string) {
+	l.attempts.Delete(key)
+}
+
+// login authenticates a username+password pair set via setPassword, as an
+// alternative to the Google OAuth flow.
+func (app *booksingApp) login(c *gin.Context) {
+	username := c.PostForm("username")
+	password := c.PostForm("password")
+	key := c.ClientIP() + "/" + username
+
+	if app.loginLimiter.blocked(key) {
+		c.HTML(http.StatusTooManyRequests, "error.html", V{Error: errTooManyAttempts})
+		return
+	}
+
+	u, err := app.db.GetUser(username)
+	if err != nil || u == nil || u.PasswordHash == "" || !verifyPassword(password, u.PasswordHash) {
+		app.loginLimiter.recordFailure(key)
+		c.HTML(http.StatusUnauthorized, "login.html", gin.H{"Error": "invalid username or password"})
+		return
+	}
+	app.loginLimiter.reset(key)
+
+	sess := sessions.Default(c)
+	sess.Set("username", u.Username)
+	if err := sess.Save(); err != nil {
+		app.logger.WithError(err).Error("failed saving session")
+		c.HTML(http.StatusInternalServerError, "error.html", V{Error: err})
+		return
+	}
+
+	c.Redirect(http.StatusFound, "/")
+}
+
+// setPassword is an admin-only endpoint to set or reset a user's password.
+func (app *booksingApp) setPassword(c *gin.Context) {
+	username := c.Param("username")
+	password := c.PostForm("password")
+	if password == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "password is required"})
+		return
+	}
+
+	u, err := app.db.GetUser(username)
+	if err != nil || u == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		return
+	}
+
+	hash, err := hashPassword(password)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	u.PasswordHash = hash
+
+	if err := app.db.SaveUser(u); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
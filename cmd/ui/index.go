@@ -0,0 +1,30 @@
+package main
+
+import (
+	"context"
+
+	"github.com/gnur/booksing"
+)
+
+// meiliUpdater drains meiliQ, enriches each parsed book with any metadata
+// the parser couldn't read from the file itself, indexes it, and
+// enqueues it for ActivityPub announcement afterwards so "new arrivals"
+// only go out for books the index accepted. AddBooks doesn't wait for the
+// index to finish processing, so with Meili a book can be announced
+// shortly before it shows up in search results.
+func (app *booksingApp) meiliUpdater() {
+	for b := range app.meiliQ {
+		if err := app.enrichBook(context.Background(), &b); err != nil {
+			app.logger.WithError(err).WithField("hash", b.Hash).Warn("failed enriching book metadata")
+		}
+
+		if err := app.s.AddBooks([]booksing.Book{b}, false); err != nil {
+			app.logger.WithError(err).WithField("hash", b.Hash).Error("failed indexing book")
+			continue
+		}
+
+		if app.cfg.ActivityPub.Enabled {
+			app.apQ <- b
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"github.com/gnur/booksing"
+	"github.com/gnur/booksing/activitypub"
+)
+
+// search abstracts the book index booksing queries against. meili is the
+// default, network-backed implementation; sqlitefts is a dependency-free
+// fallback for small self-hosted deployments.
+type search interface {
+	AddBooks(books []booksing.Book, sync bool) error
+	GetBook(hash string) (*booksing.Book, error)
+	DeleteBook(hash string) error
+	GetBooks(q string, limit, offset int64) ([]booksing.Book, error)
+	// GetBooksByHashes projects a fixed set of hashes, in the same shape as
+	// GetBooks, so shelf membership can be turned into a result page
+	// without a second round trip through full text search.
+	GetBooksByHashes(hashes []string, limit, offset int64) ([]booksing.Book, error)
+	// GetRecentBooks returns books ordered by Added, newest first. It
+	// backs the "Recently Added" OPDS feed; both implementations also
+	// answer GetBooks("") with it.
+	GetRecentBooks(limit, offset int64) ([]booksing.Book, error)
+}
+
+// database abstracts the storage backing users, downloads and other
+// booksing metadata that isn't part of the search index.
+type database interface {
+	Close() error
+	GetUser(username string) (*booksing.User, error)
+	SaveUser(u *booksing.User) error
+
+	GetShelves(username string) ([]booksing.Shelf, error)
+	GetShelf(id string) (*booksing.Shelf, error)
+	SaveShelf(s *booksing.Shelf) error
+	DeleteShelf(id string) error
+	AddToShelf(id, hash string) error
+	RemoveFromShelf(id, hash string) error
+
+	GetReadingState(username, hash string) (*booksing.ReadingState, error)
+	SaveReadingState(rs *booksing.ReadingState) error
+	GetReadingStates(username string, status booksing.ReadingStatus) ([]booksing.ReadingState, error)
+
+	GetActorKeyPair() (*activitypub.KeyPair, error)
+	SaveActorKeyPair(kp *activitypub.KeyPair) error
+}
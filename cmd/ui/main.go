@@ -15,7 +15,10 @@ import (
 	"github.com/gin-contrib/sessions/cookie"
 	"github.com/gin-gonic/gin"
 	"github.com/gnur/booksing"
+	"github.com/gnur/booksing/activitypub"
 	"github.com/gnur/booksing/meili"
+	"github.com/gnur/booksing/metadata"
+	"github.com/gnur/booksing/sqlitefts"
 	"github.com/gnur/booksing/storm"
 	"github.com/markbates/goth"
 	"github.com/markbates/goth/gothic"
@@ -60,6 +63,15 @@ type configuration struct {
 		Index string `default:"books"`
 		Key   string `required:"true"`
 	}
+	Metadata struct {
+		CacheDir       string  `default:"./metadata-cache"`
+		GoogleBooksQPS float64 `default:"1"`
+		OpenLibraryQPS float64 `default:"1"`
+	}
+	ActivityPub struct {
+		Enabled   bool `default:"false"`
+		Followers []string
+	}
 	LogLevel     string `default:"info"`
 	BindAddress  string `default:"localhost:7132"`
 	Timezone     string `default:"Europe/Amsterdam"`
@@ -85,14 +97,24 @@ func main() {
 	}
 
 	var db database
-	if strings.HasPrefix(cfg.Database, "file://") {
+	switch {
+	case strings.HasPrefix(cfg.Database, "file://"):
 		log.WithField("filedbpath", cfg.Database).Debug("using this file")
 		db, err = storm.New(strings.TrimPrefix(cfg.Database, "file://"))
 		if err != nil {
 			log.WithField("err", err).Fatal("could not create fileDB")
 		}
 		defer db.Close()
-	} else {
+	case strings.HasPrefix(cfg.Database, "sqlite://"):
+		// metadata (users, downloads, ...) still lives in storm; only the
+		// search index below moves into the same sqlite file.
+		log.WithField("filedbpath", cfg.Database).Debug("using this file")
+		db, err = storm.New(strings.TrimPrefix(cfg.Database, "sqlite://"))
+		if err != nil {
+			log.WithField("err", err).Fatal("could not create fileDB")
+		}
+		defer db.Close()
+	default:
 		log.Fatal("invalid database chosen")
 	}
 
@@ -107,9 +129,33 @@ func main() {
 	}
 
 	var s search
-	s, err = meili.New(cfg.Meili.Host, cfg.Meili.Index, cfg.Meili.Key)
+	if strings.HasPrefix(cfg.Database, "sqlite://") {
+		s, err = sqlitefts.New(strings.TrimPrefix(cfg.Database, "sqlite://"), cfg.BatchSize)
+		if err != nil {
+			log.WithField("err", err).Fatal("unable to start sqlite fts5 index")
+		}
+	} else {
+		s, err = meili.New(cfg.Meili.Host, cfg.Meili.Index, cfg.Meili.Key)
+		if err != nil {
+			log.WithField("err", err).Fatal("unable to start meili client")
+		}
+	}
+
+	metaChain, err := metadata.NewChain(
+		cfg.Metadata.CacheDir,
+		metadata.NewGoogleBooks(cfg.Metadata.GoogleBooksQPS),
+		metadata.NewOpenLibrary(cfg.Metadata.OpenLibraryQPS),
+	)
 	if err != nil {
-		log.WithField("err", err).Fatal("unable to start meili client")
+		log.WithField("err", err).Fatal("unable to start metadata fetcher")
+	}
+
+	var apPublisher *activitypub.Publisher
+	if cfg.ActivityPub.Enabled {
+		apPublisher, err = activitypub.NewPublisher(cfg.FQDN, db, cfg.ActivityPub.Followers)
+		if err != nil {
+			log.WithField("err", err).Fatal("unable to start activitypub publisher")
+		}
 	}
 
 	tpl := template.New("")
@@ -159,6 +205,10 @@ func main() {
 		meiliQ:       make(chan booksing.Book),
 		saveInterval: interval,
 		sessionMap:   sync.Map{},
+		metadata:     metaChain,
+		activityPub:  apPublisher,
+		apQ:          make(chan booksing.Book),
+		loginLimiter: newLoginLimiter(),
 	}
 
 	if cfg.ImportDir != "" {
@@ -170,6 +220,10 @@ func main() {
 		go app.meiliUpdater()
 	}
 
+	if cfg.ActivityPub.Enabled {
+		go app.activityPubLoop()
+	}
+
 	r := gin.New()
 	key := argon2.IDKey(app.cfg.Secret, salt, 4, 4*1024, 2, 32)
 	store := cookie.NewStore(key)
@@ -194,6 +248,7 @@ func main() {
 	r.GET("/login", func(c *gin.Context) {
 		c.HTML(200, "login.html", nil)
 	})
+	r.POST("/login", app.login)
 
 	qr := r.Group("/qr")
 	{
@@ -300,12 +355,45 @@ func main() {
 	auth.Use(app.BearerTokenMiddleware())
 	{
 		auth.GET("/", app.search)
-		auth.GET("/bookmarks", app.bookmarks)
+		auth.GET("/bookmarks", app.showBookmarks)
 		auth.GET("/rotateShelve/:hash", app.rotateIcon)
 		auth.POST("/rotateShelve/:hash", app.rotateIcon)
 		auth.GET("/download", app.downloadBook)
 		auth.GET("/icons/:hash", app.serveIcon)
 
+		auth.GET("/shelves", app.listShelves)
+		auth.POST("/shelves", app.createShelf)
+		auth.GET("/shelves/:id", app.showShelf)
+		auth.DELETE("/shelves/:id", app.deleteShelf)
+		auth.POST("/shelves/:id/add/:hash", app.addToShelf)
+		auth.DELETE("/shelves/:id/remove/:hash", app.removeFromShelf)
+		auth.GET("/shelves/reading", app.showCurrentlyReading)
+		auth.GET("/shelves/finished", app.showFinished)
+		auth.POST("/books/:hash/state", app.setReadingState)
+	}
+
+	if cfg.ActivityPub.Enabled {
+		r.GET("/.well-known/webfinger", app.webfinger)
+		ap := r.Group("/ap")
+		{
+			ap.GET("/actor", app.apActor)
+			ap.GET("/outbox", app.apOutbox)
+			ap.GET("/books/:hash", app.apBook)
+		}
+	}
+
+	opds := r.Group("/opds")
+	opds.Use(app.BasicAuthMiddleware())
+	{
+		opds.GET("", app.opdsRoot)
+		opds.GET("/v2", app.opdsV2Root)
+		opds.GET("/books", app.opdsAcquisition)
+		opds.GET("/recent", app.opdsRecent)
+		opds.GET("/search", app.opdsAcquisition)
+		opds.GET("/books/:hash", app.opdsBook)
+		opds.GET("/shelves/reading", app.opdsReadingStateFeed("urn:booksing:reading", "Currently Reading", booksing.StatusReading))
+		opds.GET("/shelves/finished", app.opdsReadingStateFeed("urn:booksing:finished", "Finished", booksing.StatusFinished))
+		opds.GET("/shelves/:id", app.opdsShelfFeed)
 	}
 
 	admin := r.Group("/admin")
@@ -316,6 +404,9 @@ func main() {
 		admin.POST("/delete/:hash", app.deleteBook)
 		admin.POST("user/:username", app.updateUser)
 		admin.POST("/adduser", app.addUser)
+		admin.POST("/refetch/:hash", app.refetchBook)
+		admin.POST("/refetch-all", app.refetchAll)
+		admin.POST("/user/:username/password", app.setPassword)
 	}
 
 	log.Info("booksing is now running")
@@ -336,4 +427,4 @@ func main() {
 func (app *booksingApp) IsUserAdmin(c *gin.Context) bool {
 
 	return true
-}
\ No newline at end of file
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gnur/booksing"
+)
+
+// refetchBook re-runs metadata enrichment for a single book and re-indexes
+// it once the fetch completes.
+func (app *booksingApp) refetchBook(c *gin.Context) {
+	hash := c.Param("hash")
+
+	b, err := app.s.GetBook(hash)
+	if err != nil || b == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "book not found"})
+		return
+	}
+
+	if err := app.enrichBook(c.Request.Context(), b); err != nil {
+		app.logger.WithError(err).WithField("hash", hash).Error("failed refetching metadata")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := app.s.AddBooks([]booksing.Book{*b}, true); err != nil {
+		app.logger.WithError(err).WithField("hash", hash).Error("failed re-indexing book after refetch")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, b)
+}
+
+// refetchAll re-runs metadata enrichment for every book reached by paging
+// through GetBooks(""). With the Meili backend that is at most the 2000
+// documents meili.GetRecentBooks scans, so a larger library is only partly
+// refetched. It runs in the background and returns immediately, since a
+// full library refetch can take a while once rate limiting is taken into
+// account.
+func (app *booksingApp) refetchAll(c *gin.Context) {
+	go func() {
+		// Detached from the request: c.Request.Context() is canceled as
+		// soon as this handler returns, which it already has by the time
+		// this goroutine does any real work.
+		ctx := context.Background()
+
+		var offset int64
+		const pageSize = 100
+
+		for {
+			books, err := app.s.GetBooks("", pageSize, offset)
+			if err != nil {
+				app.logger.WithError(err).Error("failed listing books for refetch-all")
+				return
+			}
+			if len(books) == 0 {
+				return
+			}
+
+			for i := range books {
+				b := books[i]
+				if err := app.enrichBook(ctx, &b); err != nil {
+					app.logger.WithError(err).WithField("hash", b.Hash).Warn("failed refetching metadata")
+					continue
+				}
+				if err := app.s.AddBooks([]booksing.Book{b}, false); err != nil {
+					app.logger.WithError(err).WithField("hash", b.Hash).Warn("failed re-indexing book after refetch")
+				}
+			}
+
+			offset += int64(len(books))
+		}
+	}()
+
+	c.JSON(http.StatusAccepted, gin.H{"status": "refetch started"})
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"context"
+	"strings"
+
+	"github.com/gnur/booksing"
+	"github.com/gnur/booksing/metadata"
+)
+
+// enrichBook fills in any Description, cover, publish date, categories or
+// page count the parser couldn't read from the book file itself, using
+// app.metadata's fallback chain of external sources. It runs both as part
+// of normal import (meiliUpdater) and on demand via the admin
+// refetch/refetchAll endpoints.
+func (app *booksingApp) enrichBook(ctx context.Context, b *booksing.Book) error {
+	res, err := app.metadata.Fetch(ctx, metadata.Query{
+		ISBN:   b.ISBN,
+		Title:  b.Title,
+		Author: b.Author,
+	})
+	if err != nil {
+		return err
+	}
+
+	if b.Description == "" {
+		b.Description = res.Description
+	}
+	if b.Cover == "" {
+		b.Cover = res.CoverURL
+	}
+	if b.PublishedDate == "" {
+		b.PublishedDate = res.PublishedDate
+	}
+	if b.Categories == "" && len(res.Categories) > 0 {
+		b.Categories = strings.Join(res.Categories, ", ")
+	}
+	if b.PageCount == 0 {
+		b.PageCount = res.PageCount
+	}
+
+	return nil
+}
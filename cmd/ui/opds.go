@@ -0,0 +1,160 @@
+package main
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gnur/booksing/opds"
+)
+
+const opdsPageSize = 25
+
+// BasicAuthMiddleware authenticates headless OPDS clients (KOReader, Moon+
+// Reader, ...) that can't follow the Google OAuth or QR login flows. It
+// verifies against the same user store as the rest of the app and stores
+// the username on the context, mirroring BearerTokenMiddleware.
+func (app *booksingApp) BasicAuthMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		username, password, ok := c.Request.BasicAuth()
+		if !ok {
+			c.Header("WWW-Authenticate", `Basic realm="booksing"`)
+			c.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+
+		u, err := app.db.GetUser(username)
+		if err != nil || u == nil || subtle.ConstantTimeCompare([]byte(password), []byte(u.Token)) != 1 {
+			c.Header("WWW-Authenticate", `Basic realm="booksing"`)
+			c.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+
+		c.Set("username", username)
+		c.Next()
+	}
+}
+
+// opdsRoot serves the top-level OPDS navigation feed: all books, recently
+// added, currently reading and finished. There are no "By Author" or "By
+// Series" facets; the search interface has no grouping query, and Book
+// has no Series field.
+func (app *booksingApp) opdsRoot(c *gin.Context) {
+	feed := opds.NewFeed("urn:booksing:root", "booksing")
+	feed.Links = []opds.Link{
+		{Rel: "self", Href: "/opds", Type: opds.MimeNavigation},
+		{Rel: "start", Href: "/opds", Type: opds.MimeNavigation},
+		{Rel: "search", Href: "/opds/search?q={searchTerms}", Type: opds.MimeAcquisition},
+	}
+	feed.Entries = []opds.Entry{
+		opds.NewNavEntry("All books", "/opds/books"),
+		opds.NewNavEntry("Recently Added", "/opds/recent"),
+		opds.NewNavEntry("Currently Reading", "/opds/shelves/reading"),
+		opds.NewNavEntry("Finished", "/opds/shelves/finished"),
+	}
+
+	c.Header("Content-Type", opds.MimeNavigation)
+	c.XML(http.StatusOK, feed)
+}
+
+// opdsAcquisition serves a paginated acquisition feed over GetBooks,
+// optionally filtered by the "q" query param.
+func (app *booksingApp) opdsAcquisition(c *gin.Context) {
+	q := c.Query("q")
+	offset, _ := strconv.ParseInt(c.DefaultQuery("offset", "0"), 10, 64)
+
+	books, err := app.s.GetBooks(q, opdsPageSize, offset)
+	if err != nil {
+		c.XML(http.StatusInternalServerError, opds.NewFeed("urn:booksing:error", "error"))
+		return
+	}
+
+	feed := opds.NewFeed("urn:booksing:books", "All books")
+	feed.Links = []opds.Link{
+		{Rel: "self", Href: c.Request.URL.String(), Type: opds.MimeAcquisition},
+		{Rel: "start", Href: "/opds", Type: opds.MimeNavigation},
+	}
+	if len(books) == opdsPageSize {
+		next := fmt.Sprintf("/opds/books?q=%s&offset=%d", q, offset+opdsPageSize)
+		feed.Links = append(feed.Links, opds.Link{Rel: "next", Href: next, Type: opds.MimeAcquisition})
+	}
+
+	for _, b := range books {
+		downloadURL := fmt.Sprintf("/download?hash=%s", b.Hash)
+		coverURL := fmt.Sprintf("/icons/%s", b.Hash)
+		feed.Entries = append(feed.Entries, opds.NewBookEntry(b, downloadURL, coverURL))
+	}
+
+	c.Header("Content-Type", opds.MimeAcquisition)
+	c.XML(http.StatusOK, feed)
+}
+
+// opdsRecent serves the most recently added books, ignoring any "q" the
+// client sends. Without a "q", /opds/books returns the same books.
+func (app *booksingApp) opdsRecent(c *gin.Context) {
+	offset, _ := strconv.ParseInt(c.DefaultQuery("offset", "0"), 10, 64)
+
+	books, err := app.s.GetRecentBooks(opdsPageSize, offset)
+	if err != nil {
+		c.XML(http.StatusInternalServerError, opds.NewFeed("urn:booksing:error", "error"))
+		return
+	}
+
+	feed := opds.NewFeed("urn:booksing:recent", "Recently Added")
+	feed.Links = []opds.Link{
+		{Rel: "self", Href: c.Request.URL.String(), Type: opds.MimeAcquisition},
+		{Rel: "start", Href: "/opds", Type: opds.MimeNavigation},
+	}
+	if len(books) == opdsPageSize {
+		next := fmt.Sprintf("/opds/recent?offset=%d", offset+opdsPageSize)
+		feed.Links = append(feed.Links, opds.Link{Rel: "next", Href: next, Type: opds.MimeAcquisition})
+	}
+
+	for _, b := range books {
+		downloadURL := fmt.Sprintf("/download?hash=%s", b.Hash)
+		coverURL := fmt.Sprintf("/icons/%s", b.Hash)
+		feed.Entries = append(feed.Entries, opds.NewBookEntry(b, downloadURL, coverURL))
+	}
+
+	c.Header("Content-Type", opds.MimeAcquisition)
+	c.XML(http.StatusOK, feed)
+}
+
+// opdsBook serves a single acquisition entry for one book, keyed by hash.
+func (app *booksingApp) opdsBook(c *gin.Context) {
+	hash := c.Param("hash")
+	b, err := app.s.GetBook(hash)
+	if err != nil || b == nil {
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+
+	downloadURL := fmt.Sprintf("/download?hash=%s", b.Hash)
+	coverURL := fmt.Sprintf("/icons/%s", b.Hash)
+	entry := opds.NewBookEntry(*b, downloadURL, coverURL)
+
+	c.Header("Content-Type", opds.MimeAcquisition)
+	c.XML(http.StatusOK, entry)
+}
+
+// opdsV2Root serves the OPDS 2.0 JSON equivalent of opdsRoot, for clients
+// that prefer the newer format.
+func (app *booksingApp) opdsV2Root(c *gin.Context) {
+	feed := opds.Feed2{
+		Metadata: opds.Metadata2{Title: "booksing"},
+		Links: []opds.Link2{
+			{Rel: "self", Href: "/opds/v2", Type: opds.MimeOPDS2},
+		},
+		Navigation: []opds.Link2{
+			{Href: "/opds/books", Title: "All books", Type: opds.MimeAcquisition},
+			{Href: "/opds/recent", Title: "Recently Added", Type: opds.MimeAcquisition},
+			{Href: "/opds/shelves/reading", Title: "Currently Reading", Type: opds.MimeAcquisition},
+			{Href: "/opds/shelves/finished", Title: "Finished", Type: opds.MimeAcquisition},
+		},
+	}
+
+	c.Header("Content-Type", opds.MimeOPDS2)
+	c.JSON(http.StatusOK, feed)
+}
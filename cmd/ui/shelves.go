@@ -0,0 +1,306 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gnur/booksing"
+	"github.com/gnur/booksing/opds"
+)
+
+const shelfPageSize = 50
+
+// bookmarksShelfID is the fixed, per-user id of the builtin shelf that
+// replaces the old standalone bookmarks list.
+func bookmarksShelfID(username string) string {
+	return username + "/bookmarks"
+}
+
+func username(c *gin.Context) string {
+	u, _ := c.Get("username")
+	name, _ := u.(string)
+	return name
+}
+
+// ensureBuiltinShelf returns the named builtin shelf for a user, creating
+// it on first use. Only a genuine "no such shelf" is treated as first use;
+// any other error (e.g. a transient storage failure) is returned as-is so
+// callers don't overwrite an existing shelf because of an unrelated error.
+func (app *booksingApp) ensureBuiltinShelf(username, id, name string) (*booksing.Shelf, error) {
+	shelf, err := app.db.GetShelf(id)
+	switch {
+	case err == nil:
+		return shelf, nil
+	case !errors.Is(err, booksing.ErrNotFound):
+		return nil, err
+	}
+
+	shelf = &booksing.Shelf{
+		ID:      id,
+		Owner:   username,
+		Name:    name,
+		Kind:    booksing.ShelfBuiltin,
+		Created: time.Now(),
+	}
+	if err := app.db.SaveShelf(shelf); err != nil {
+		return nil, err
+	}
+	return shelf, nil
+}
+
+// readingStateBooks resolves the books a user has in the given reading
+// status, backing the virtual "Currently Reading" and "Finished" shelves.
+func (app *booksingApp) readingStateBooks(username string, status booksing.ReadingStatus) ([]booksing.Book, error) {
+	states, err := app.db.GetReadingStates(username, status)
+	if err != nil {
+		return nil, err
+	}
+
+	hashes := make([]string, len(states))
+	for i, rs := range states {
+		hashes[i] = rs.BookHash
+	}
+
+	return app.s.GetBooksByHashes(hashes, shelfPageSize, 0)
+}
+
+// listShelves shows every shelf owned by the current user: their custom
+// shelves, plus the builtin bookmarks shelf and the virtual
+// currently-reading/finished shelves.
+func (app *booksingApp) listShelves(c *gin.Context) {
+	user := username(c)
+
+	if _, err := app.ensureBuiltinShelf(user, bookmarksShelfID(user), "Bookmarks"); err != nil {
+		c.HTML(http.StatusInternalServerError, "error.html", V{Error: err})
+		return
+	}
+
+	shelves, err := app.db.GetShelves(user)
+	if err != nil {
+		c.HTML(http.StatusInternalServerError, "error.html", V{Error: err})
+		return
+	}
+
+	c.HTML(http.StatusOK, "shelves.html", gin.H{"Shelves": shelves})
+}
+
+// showBookmarks renders the builtin bookmarks shelf, replacing the old
+// standalone bookmarks handler.
+func (app *booksingApp) showBookmarks(c *gin.Context) {
+	user := username(c)
+
+	shelf, err := app.ensureBuiltinShelf(user, bookmarksShelfID(user), "Bookmarks")
+	if err != nil {
+		c.HTML(http.StatusInternalServerError, "error.html", V{Error: err})
+		return
+	}
+
+	books, err := app.s.GetBooksByHashes(shelf.Hashes, shelfPageSize, 0)
+	if err != nil {
+		c.HTML(http.StatusInternalServerError, "error.html", V{Error: err})
+		return
+	}
+
+	c.HTML(http.StatusOK, "shelf.html", gin.H{"Shelf": shelf, "Books": books})
+}
+
+// showCurrentlyReading renders the virtual "Currently Reading" shelf,
+// derived from ReadingState rather than stored shelf membership.
+func (app *booksingApp) showCurrentlyReading(c *gin.Context) {
+	books, err := app.readingStateBooks(username(c), booksing.StatusReading)
+	if err != nil {
+		c.HTML(http.StatusInternalServerError, "error.html", V{Error: err})
+		return
+	}
+	c.HTML(http.StatusOK, "shelf.html", gin.H{"Shelf": gin.H{"Name": "Currently Reading"}, "Books": books})
+}
+
+// showFinished renders the virtual "Finished" shelf, derived from
+// ReadingState rather than stored shelf membership.
+func (app *booksingApp) showFinished(c *gin.Context) {
+	books, err := app.readingStateBooks(username(c), booksing.StatusFinished)
+	if err != nil {
+		c.HTML(http.StatusInternalServerError, "error.html", V{Error: err})
+		return
+	}
+	c.HTML(http.StatusOK, "shelf.html", gin.H{"Shelf": gin.H{"Name": "Finished"}, "Books": books})
+}
+
+// createShelf makes a new, empty custom shelf for the current user.
+func (app *booksingApp) createShelf(c *gin.Context) {
+	name := c.PostForm("name")
+	if name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "name is required"})
+		return
+	}
+
+	s := booksing.Shelf{
+		ID:      username(c) + "/" + randID(),
+		Owner:   username(c),
+		Name:    name,
+		Kind:    booksing.ShelfCustom,
+		Created: time.Now(),
+	}
+	if err := app.db.SaveShelf(&s); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, s)
+}
+
+// showShelf renders the first shelfPageSize books of a single shelf; it
+// has no pagination, so books beyond that are not shown.
+func (app *booksingApp) showShelf(c *gin.Context) {
+	id := c.Param("id")
+	shelf, err := app.db.GetShelf(id)
+	if err != nil || shelf == nil || shelf.Owner != username(c) {
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+
+	books, err := app.s.GetBooksByHashes(shelf.Hashes, shelfPageSize, 0)
+	if err != nil {
+		c.HTML(http.StatusInternalServerError, "error.html", V{Error: err})
+		return
+	}
+
+	c.HTML(http.StatusOK, "shelf.html", gin.H{"Shelf": shelf, "Books": books})
+}
+
+// deleteShelf removes a custom shelf. Builtin shelves can't be deleted.
+func (app *booksingApp) deleteShelf(c *gin.Context) {
+	id := c.Param("id")
+	shelf, err := app.db.GetShelf(id)
+	if err != nil || shelf == nil || shelf.Owner != username(c) {
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+	if shelf.Kind == booksing.ShelfBuiltin {
+		c.JSON(http.StatusForbidden, gin.H{"error": "builtin shelves can't be deleted"})
+		return
+	}
+
+	if err := app.db.DeleteShelf(id); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// addToShelf adds a book to a shelf the current user owns.
+func (app *booksingApp) addToShelf(c *gin.Context) {
+	id := c.Param("id")
+	hash := c.Param("hash")
+
+	shelf, err := app.db.GetShelf(id)
+	if err != nil || shelf == nil || shelf.Owner != username(c) {
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+
+	if err := app.db.AddToShelf(id, hash); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// removeFromShelf removes a book from a shelf the current user owns.
+func (app *booksingApp) removeFromShelf(c *gin.Context) {
+	id := c.Param("id")
+	hash := c.Param("hash")
+
+	shelf, err := app.db.GetShelf(id)
+	if err != nil || shelf == nil || shelf.Owner != username(c) {
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+
+	if err := app.db.RemoveFromShelf(id, hash); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// setReadingState updates the current user's progress on a book, driving
+// the "Currently Reading" and "Finished" virtual shelves.
+func (app *booksingApp) setReadingState(c *gin.Context) {
+	hash := c.Param("hash")
+
+	var body struct {
+		Status   booksing.ReadingStatus `json:"status" binding:"required"`
+		Progress float64                `json:"progress"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rs := booksing.ReadingState{
+		Username: username(c),
+		BookHash: hash,
+		Status:   body.Status,
+		Progress: body.Progress,
+		LastRead: time.Now(),
+	}
+	if err := app.db.SaveReadingState(&rs); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, rs)
+}
+
+// opdsShelfFeed serves a stored shelf (the builtin bookmarks shelf or a
+// user's custom shelf) as an OPDS acquisition feed. The virtual
+// "Currently Reading" and "Finished" shelves are served by
+// opdsReadingStateFeed instead.
+func (app *booksingApp) opdsShelfFeed(c *gin.Context) {
+	id := c.Param("id")
+	shelf, err := app.db.GetShelf(id)
+	if err != nil || shelf == nil || shelf.Owner != username(c) {
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+
+	books, err := app.s.GetBooksByHashes(shelf.Hashes, shelfPageSize, 0)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	feed := opds.NewFeed("urn:booksing:shelf:"+shelf.ID, shelf.Name)
+	for _, b := range books {
+		downloadURL := "/download?hash=" + b.Hash
+		coverURL := "/icons/" + b.Hash
+		feed.Entries = append(feed.Entries, opds.NewBookEntry(b, downloadURL, coverURL))
+	}
+
+	c.Header("Content-Type", opds.MimeAcquisition)
+	c.XML(http.StatusOK, feed)
+}
+
+// opdsReadingStateFeed serves the virtual "Currently Reading" or "Finished"
+// shelf as an OPDS acquisition feed.
+func (app *booksingApp) opdsReadingStateFeed(urn, name string, status booksing.ReadingStatus) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		books, err := app.readingStateBooks(username(c), status)
+		if err != nil {
+			c.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		feed := opds.NewFeed(urn, name)
+		for _, b := range books {
+			downloadURL := "/download?hash=" + b.Hash
+			coverURL := "/icons/" + b.Hash
+			feed.Entries = append(feed.Entries, opds.NewBookEntry(b, downloadURL, coverURL))
+		}
+
+		c.Header("Content-Type", opds.MimeAcquisition)
+		c.XML(http.StatusOK, feed)
+	}
+}
@@ -0,0 +1,11 @@
+package booksing
+
+import "time"
+
+// Download records a single book download, for the admin downloads view.
+type Download struct {
+	ID       int `storm:"id,increment"`
+	Username string
+	BookHash string
+	When     time.Time
+}
@@ -0,0 +1,11 @@
+package booksing
+
+import "errors"
+
+// ErrNotFound is returned, wrapped, by shelf lookups when the requested
+// shelf doesn't exist, as opposed to a transient storage error. User and
+// reading state lookups return the storage layer's own error instead.
+// Callers should treat the two cases differently:
+// not-found usually means "create it" or "404", while any other error
+// means the lookup itself failed and shouldn't be papered over.
+var ErrNotFound = errors.New("not found")
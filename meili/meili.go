@@ -2,6 +2,7 @@ package meili
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 
@@ -9,6 +10,13 @@ import (
 	"github.com/meilisearch/meilisearch-go"
 )
 
+// recentScanLimit bounds how many documents GetRecentBooks pulls from
+// Meili to sort client-side, since this version of the Meili API has no
+// per-query sort. Only the first recentScanLimit documents Meili lists are
+// sorted, so in a larger index the newest books overall can be missed and
+// an offset past recentScanLimit returns no books.
+const recentScanLimit = 2000
+
 type Meili struct {
 	client *meilisearch.Client
 	index  string
@@ -78,40 +86,22 @@ func (s *Meili) DeleteBook(hash string) error {
 }
 
 func (s *Meili) GetBooks(q string, limit, offset int64) ([]booksing.Book, error) {
+	if q == "" {
+		return s.GetRecentBooks(limit, offset)
+	}
 
 	var books []booksing.Book
-	var hits []interface{}
-
-	if q == "" {
-		for tDiff := 0 * time.Hour; tDiff < 720*time.Hour; tDiff += 24 * time.Hour {
-			q := time.Now().Add(-1 * tDiff).Format("2006-01-02")
-			res, err := s.client.Search(s.index).Search(meilisearch.SearchRequest{
-				Query:  q,
-				Limit:  limit,
-				Offset: offset,
-			})
-			if err != nil {
-				return nil, fmt.Errorf("Unable to get results from meili: %w", err)
-			}
-			if len(res.Hits) > 0 {
-				hits = res.Hits
-				break
-			}
-		}
-	} else {
 
-		res, err := s.client.Search(s.index).Search(meilisearch.SearchRequest{
-			Query:  q,
-			Limit:  limit,
-			Offset: offset,
-		})
-		if err != nil {
-			return nil, fmt.Errorf("Unable to get results from meili: %w", err)
-		}
-		hits = res.Hits
+	res, err := s.client.Search(s.index).Search(meilisearch.SearchRequest{
+		Query:  q,
+		Limit:  limit,
+		Offset: offset,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Unable to get results from meili: %w", err)
 	}
 
-	for _, hit := range hits {
+	for _, hit := range res.Hits {
 		m, ok := hit.(map[string]interface{})
 		if !ok {
 			continue
@@ -129,8 +119,56 @@ func (s *Meili) GetBooks(q string, limit, offset int64) ([]booksing.Book, error)
 	return books, nil
 }
 
-func (s *Meili) GetBookByHash(hash string) (*booksing.Book, error) {
-	var b booksing.Book
-	err := s.client.Documents(s.index).Get(hash, &b)
-	return &b, err
-}
\ No newline at end of file
+// GetRecentBooks returns books ordered by Added, newest first. This
+// version of the Meili client has no per-query sort, so it lists a
+// bounded window of documents and sorts them client-side instead of
+// reusing the date-guessing search GetBooks("") used to do, which only
+// found whichever day had the first hit and ignored offset beyond it.
+func (s *Meili) GetRecentBooks(limit, offset int64) ([]booksing.Book, error) {
+	var docs []booksing.Book
+	err := s.client.Documents(s.index).List(meilisearch.ListDocumentsRequest{
+		Limit: recentScanLimit,
+	}, &docs)
+	if err != nil {
+		return nil, fmt.Errorf("unable to list books from meili: %w", err)
+	}
+
+	sort.Slice(docs, func(i, j int) bool {
+		return docs[i].Added.After(docs[j].Added)
+	})
+
+	start := offset
+	if start > int64(len(docs)) {
+		start = int64(len(docs))
+	}
+	end := start + limit
+	if end > int64(len(docs)) {
+		end = int64(len(docs))
+	}
+
+	return docs[start:end], nil
+}
+
+// GetBooksByHashes fetches a fixed set of books by id, e.g. the members of
+// a shelf, paginating the hash list itself since Meili has no "IN" filter.
+func (s *Meili) GetBooksByHashes(hashes []string, limit, offset int64) ([]booksing.Book, error) {
+	start := offset
+	if start > int64(len(hashes)) {
+		start = int64(len(hashes))
+	}
+	end := start + limit
+	if end > int64(len(hashes)) {
+		end = int64(len(hashes))
+	}
+
+	var books []booksing.Book
+	for _, hash := range hashes[start:end] {
+		b, err := s.GetBook(hash)
+		if err != nil {
+			continue
+		}
+		books = append(books, *b)
+	}
+
+	return books, nil
+}
@@ -0,0 +1,67 @@
+package metadata
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"golang.org/x/time/rate"
+)
+
+// GoogleBooks fetches metadata from the Google Books volumes API.
+type GoogleBooks struct {
+	client  *http.Client
+	limiter *rate.Limiter
+}
+
+// NewGoogleBooks builds a GoogleBooks fetcher limited to qps requests/sec.
+func NewGoogleBooks(qps float64) *GoogleBooks {
+	return &GoogleBooks{
+		client:  &http.Client{Timeout: defaultTimeout},
+		limiter: newLimiter(qps),
+	}
+}
+
+type googleBooksResponse struct {
+	Items []struct {
+		VolumeInfo struct {
+			Description   string   `json:"description"`
+			PublishedDate string   `json:"publishedDate"`
+			Categories    []string `json:"categories"`
+			PageCount     int      `json:"pageCount"`
+			ImageLinks    struct {
+				Thumbnail string `json:"thumbnail"`
+			} `json:"imageLinks"`
+		} `json:"volumeInfo"`
+	} `json:"items"`
+}
+
+// Fetch queries Google Books by ISBN when available, otherwise by
+// title/author.
+func (g *GoogleBooks) Fetch(ctx context.Context, q Query) (*Result, error) {
+	var query string
+	if q.ISBN != "" {
+		query = "isbn:" + q.ISBN
+	} else {
+		query = fmt.Sprintf("intitle:%s+inauthor:%s", queryEscape(q.Title), queryEscape(q.Author))
+	}
+
+	rawurl := fmt.Sprintf("https://www.googleapis.com/books/v1/volumes?q=%s", query)
+
+	var resp googleBooksResponse
+	if err := get(ctx, g.client, g.limiter, rawurl, &resp); err != nil {
+		return nil, err
+	}
+	if len(resp.Items) == 0 {
+		return &Result{}, nil
+	}
+
+	info := resp.Items[0].VolumeInfo
+	return &Result{
+		Description:   info.Description,
+		CoverURL:      info.ImageLinks.Thumbnail,
+		PublishedDate: info.PublishedDate,
+		Categories:    info.Categories,
+		PageCount:     info.PageCount,
+	}, nil
+}
@@ -0,0 +1,197 @@
+// Package metadata enriches freshly imported books with the description,
+// cover image, publish date, categories and page count that EPUB/PDF
+// metadata often lacks, by querying Google Books and Open Library.
+package metadata
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// Result is the metadata a Fetcher was able to find for a book. Zero
+// values mean the source didn't have that field.
+type Result struct {
+	Description   string
+	CoverURL      string
+	PublishedDate string
+	Categories    []string
+	PageCount     int
+}
+
+// merge fills in any fields left empty in r with non-empty values from other.
+func (r *Result) merge(other *Result) {
+	if r.Description == "" {
+		r.Description = other.Description
+	}
+	if r.CoverURL == "" {
+		r.CoverURL = other.CoverURL
+	}
+	if r.PublishedDate == "" {
+		r.PublishedDate = other.PublishedDate
+	}
+	if len(r.Categories) == 0 {
+		r.Categories = other.Categories
+	}
+	if r.PageCount == 0 {
+		r.PageCount = other.PageCount
+	}
+}
+
+func (r *Result) empty() bool {
+	return r.Description == "" && r.CoverURL == "" && r.PublishedDate == "" &&
+		len(r.Categories) == 0 && r.PageCount == 0
+}
+
+// Query is what callers know about a book before enrichment.
+type Query struct {
+	ISBN   string
+	Title  string
+	Author string
+}
+
+// Fetcher looks up metadata for a book from a single source.
+type Fetcher interface {
+	Fetch(ctx context.Context, q Query) (*Result, error)
+}
+
+// Chain tries a list of Fetchers in order, caching results on disk and
+// filling in gaps from later sources when an earlier one is incomplete.
+type Chain struct {
+	sources []Fetcher
+	cache   *diskCache
+}
+
+// NewChain builds a fallback chain over sources, caching responses under
+// cacheDir keyed by ISBN or title+author.
+func NewChain(cacheDir string, sources ...Fetcher) (*Chain, error) {
+	c, err := newDiskCache(cacheDir)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create metadata cache: %w", err)
+	}
+
+	return &Chain{sources: sources, cache: c}, nil
+}
+
+// Fetch queries each source in order, merging their results, and stops
+// early once every field has been filled in. Non-empty results are cached
+// on disk; a lookup that finds nothing is not cached.
+func (c *Chain) Fetch(ctx context.Context, q Query) (*Result, error) {
+	key := cacheKey(q)
+
+	if cached, ok := c.cache.get(key); ok {
+		return cached, nil
+	}
+
+	result := &Result{}
+	for _, src := range c.sources {
+		r, err := src.Fetch(ctx, q)
+		if err != nil {
+			continue
+		}
+		if r == nil {
+			continue
+		}
+		result.merge(r)
+		if result.Description != "" && result.CoverURL != "" && result.PublishedDate != "" &&
+			len(result.Categories) > 0 && result.PageCount != 0 {
+			break
+		}
+	}
+
+	if result.empty() {
+		return result, nil
+	}
+
+	c.cache.set(key, result)
+	return result, nil
+}
+
+func cacheKey(q Query) string {
+	raw := q.ISBN
+	if raw == "" {
+		raw = q.Title + "|" + q.Author
+	}
+	sum := sha1.Sum([]byte(raw))
+	return hex.EncodeToString(sum[:])
+}
+
+// diskCache persists Results as JSON files, one per cache key.
+type diskCache struct {
+	dir string
+}
+
+func newDiskCache(dir string) (*diskCache, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &diskCache{dir: dir}, nil
+}
+
+func (c *diskCache) get(key string) (*Result, bool) {
+	b, err := os.ReadFile(filepath.Join(c.dir, key+".json"))
+	if err != nil {
+		return nil, false
+	}
+
+	var r Result
+	if err := json.Unmarshal(b, &r); err != nil {
+		return nil, false
+	}
+	return &r, true
+}
+
+func (c *diskCache) set(key string, r *Result) {
+	b, err := json.Marshal(r)
+	if err != nil {
+		return
+	}
+	_ = os.WriteFile(filepath.Join(c.dir, key+".json"), b, 0o644)
+}
+
+// newLimiter returns a token-bucket limiter allowing qps requests per
+// second (1 if qps isn't positive), for use by a Fetcher.
+func newLimiter(qps float64) *rate.Limiter {
+	if qps <= 0 {
+		qps = 1
+	}
+	return rate.NewLimiter(rate.Limit(qps), 1)
+}
+
+func get(ctx context.Context, client *http.Client, limiter *rate.Limiter, rawurl string, out interface{}) error {
+	if err := limiter.Wait(ctx); err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawurl, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("unable to query %s: %w", rawurl, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %d from %s", resp.StatusCode, rawurl)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+func queryEscape(s string) string {
+	return url.QueryEscape(s)
+}
+
+const defaultTimeout = 10 * time.Second
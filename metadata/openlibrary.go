@@ -0,0 +1,78 @@
+package metadata
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"golang.org/x/time/rate"
+)
+
+// OpenLibrary fetches metadata from the Open Library books API. It only
+// has enough information to look books up by ISBN.
+type OpenLibrary struct {
+	client  *http.Client
+	limiter *rate.Limiter
+}
+
+// NewOpenLibrary builds an OpenLibrary fetcher limited to qps requests/sec.
+func NewOpenLibrary(qps float64) *OpenLibrary {
+	return &OpenLibrary{
+		client:  &http.Client{Timeout: defaultTimeout},
+		limiter: newLimiter(qps),
+	}
+}
+
+type openLibraryBook struct {
+	Excerpts []struct {
+		Text string `json:"text"`
+	} `json:"excerpts"`
+	Notes         string `json:"notes"`
+	PublishDate   string `json:"publish_date"`
+	NumberOfPages int    `json:"number_of_pages"`
+	Subjects      []struct {
+		Name string `json:"name"`
+	} `json:"subjects"`
+	Cover struct {
+		Medium string `json:"medium"`
+	} `json:"cover"`
+}
+
+// Fetch looks a book up by ISBN; it returns an empty Result for any other
+// query, since Open Library's bibkeys API only indexes by identifier.
+func (o *OpenLibrary) Fetch(ctx context.Context, q Query) (*Result, error) {
+	if q.ISBN == "" {
+		return &Result{}, nil
+	}
+
+	rawurl := fmt.Sprintf(
+		"https://openlibrary.org/api/books?bibkeys=ISBN:%s&format=json&jscmd=data",
+		queryEscape(q.ISBN),
+	)
+
+	resp := map[string]openLibraryBook{}
+	if err := get(ctx, o.client, o.limiter, rawurl, &resp); err != nil {
+		return nil, err
+	}
+
+	book, ok := resp["ISBN:"+q.ISBN]
+	if !ok {
+		return &Result{}, nil
+	}
+
+	r := &Result{
+		PublishedDate: book.PublishDate,
+		PageCount:     book.NumberOfPages,
+		CoverURL:      book.Cover.Medium,
+	}
+	if len(book.Excerpts) > 0 {
+		r.Description = book.Excerpts[0].Text
+	} else {
+		r.Description = book.Notes
+	}
+	for _, s := range book.Subjects {
+		r.Categories = append(r.Categories, s.Name)
+	}
+
+	return r, nil
+}
@@ -0,0 +1,169 @@
+// Package opds builds OPDS 1.2 (Atom) and OPDS 2.0 (JSON) catalog feeds
+// from booksing books, so e-reader clients such as KOReader and Moon+
+// Reader can browse and download a booksing library directly.
+package opds
+
+import (
+	"encoding/xml"
+	"time"
+
+	"github.com/gnur/booksing"
+)
+
+// MIME types used on OPDS links, as defined by the OPDS 1.2 and 2.0 specs.
+const (
+	MimeNavigation  = "application/atom+xml;profile=opds-catalog;kind=navigation"
+	MimeAcquisition = "application/atom+xml;profile=opds-catalog;kind=acquisition"
+	MimeOPDS2       = "application/opds+json"
+	MimeOPDS2Nav    = "application/opds+json;type=navigation"
+)
+
+const nsAtom = "http://www.w3.org/2005/Atom"
+const nsOPDS = "http://opds-spec.org/2010/catalog"
+
+// Feed is the root Atom element shared by navigation and acquisition feeds.
+type Feed struct {
+	XMLName   xml.Name  `xml:"feed"`
+	Xmlns     string    `xml:"xmlns,attr"`
+	XmlnsOPDS string    `xml:"xmlns:opds,attr"`
+	ID        string    `xml:"id"`
+	Title     string    `xml:"title"`
+	Updated   time.Time `xml:"updated"`
+	Icon      string    `xml:"icon,omitempty"`
+	Author    Author    `xml:"author"`
+	Links     []Link    `xml:"link"`
+	Entries   []Entry   `xml:"entry"`
+}
+
+// Author identifies the catalog owner, required by the Atom spec.
+type Author struct {
+	Name string `xml:"name"`
+	URI  string `xml:"uri,omitempty"`
+}
+
+// Link is a single Atom/OPDS link, e.g. an acquisition or navigation link.
+type Link struct {
+	Rel   string `xml:"rel,attr,omitempty"`
+	Href  string `xml:"href,attr"`
+	Type  string `xml:"type,attr,omitempty"`
+	Title string `xml:"title,attr,omitempty"`
+}
+
+// Entry is a single catalog entry, either a navigation link or a book.
+type Entry struct {
+	ID      string    `xml:"id"`
+	Title   string    `xml:"title"`
+	Updated time.Time `xml:"updated"`
+	Content *Content  `xml:"content,omitempty"`
+	Authors []Author  `xml:"author,omitempty"`
+	Links   []Link    `xml:"link"`
+}
+
+// Content holds the human readable body of an entry, usually a description.
+type Content struct {
+	Type string `xml:"type,attr"`
+	Text string `xml:",chardata"`
+}
+
+// NewFeed builds an empty feed with the namespaces OPDS clients expect.
+func NewFeed(id, title string) *Feed {
+	return &Feed{
+		Xmlns:     nsAtom,
+		XmlnsOPDS: nsOPDS,
+		ID:        id,
+		Title:     title,
+		Updated:   time.Now(),
+		Author:    Author{Name: "booksing"},
+	}
+}
+
+// NewNavEntry builds a navigation entry pointing at another feed.
+func NewNavEntry(title, href string) Entry {
+	return Entry{
+		ID:      "urn:booksing:nav:" + href,
+		Title:   title,
+		Updated: time.Now(),
+		Links: []Link{
+			{Rel: "subsection", Href: href, Type: MimeAcquisition, Title: title},
+		},
+	}
+}
+
+// NewBookEntry builds an acquisition entry for a single book, linking back
+// to the existing /download and /icons/:hash handlers.
+func NewBookEntry(b booksing.Book, downloadURL, coverURL string) Entry {
+	e := Entry{
+		ID:      "urn:booksing:book:" + b.Hash,
+		Title:   b.Title,
+		Updated: b.Added,
+		Authors: []Author{{Name: b.Author}},
+		Links: []Link{
+			{Rel: "http://opds-spec.org/acquisition", Href: downloadURL, Type: "application/epub+zip"},
+		},
+	}
+	if b.Description != "" {
+		e.Content = &Content{Type: "text", Text: b.Description}
+	}
+	if coverURL != "" {
+		e.Links = append(e.Links,
+			Link{Rel: "http://opds-spec.org/image", Href: coverURL, Type: "image/jpeg"},
+			Link{Rel: "http://opds-spec.org/image/thumbnail", Href: coverURL, Type: "image/jpeg"},
+		)
+	}
+	return e
+}
+
+// Feed2 is the OPDS 2.0 JSON equivalent of Feed, used by newer clients.
+type Feed2 struct {
+	Metadata     Metadata2      `json:"metadata"`
+	Links        []Link2        `json:"links"`
+	Navigation   []Link2        `json:"navigation,omitempty"`
+	Publications []Publication2 `json:"publications,omitempty"`
+}
+
+// Metadata2 describes an OPDS 2.0 feed.
+type Metadata2 struct {
+	Title    string `json:"title"`
+	Modified string `json:"modified"`
+}
+
+// Link2 is an OPDS 2.0 link object.
+type Link2 struct {
+	Rel   string `json:"rel,omitempty"`
+	Href  string `json:"href"`
+	Type  string `json:"type,omitempty"`
+	Title string `json:"title,omitempty"`
+}
+
+// Publication2 is an OPDS 2.0 publication, the JSON counterpart of Entry.
+type Publication2 struct {
+	Metadata PubMetadata2 `json:"metadata"`
+	Links    []Link2      `json:"links"`
+	Images   []Link2      `json:"images,omitempty"`
+}
+
+// PubMetadata2 holds the descriptive fields of a Publication2.
+type PubMetadata2 struct {
+	Title    string   `json:"title"`
+	Author   string   `json:"author,omitempty"`
+	Modified string   `json:"modified,omitempty"`
+	Language []string `json:"language,omitempty"`
+}
+
+// NewPublication builds an OPDS 2.0 publication for a single book.
+func NewPublication(b booksing.Book, downloadURL, coverURL string) Publication2 {
+	p := Publication2{
+		Metadata: PubMetadata2{
+			Title:    b.Title,
+			Author:   b.Author,
+			Modified: b.Added.Format(time.RFC3339),
+		},
+		Links: []Link2{
+			{Rel: "http://opds-spec.org/acquisition", Href: downloadURL, Type: "application/epub+zip"},
+		},
+	}
+	if coverURL != "" {
+		p.Images = append(p.Images, Link2{Href: coverURL, Type: "image/jpeg"})
+	}
+	return p
+}
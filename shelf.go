@@ -0,0 +1,46 @@
+package booksing
+
+import "time"
+
+// ShelfKind distinguishes a user-created shelf from a built-in one such as
+// bookmarks. Both keep their membership in Hashes; the virtual "Currently
+// Reading" and "Finished" shelves are derived from ReadingState and are
+// not stored as Shelf records at all.
+type ShelfKind string
+
+// Known shelf kinds.
+const (
+	ShelfCustom  ShelfKind = "custom"
+	ShelfBuiltin ShelfKind = "builtin"
+)
+
+// Shelf is a named collection of books belonging to a user.
+type Shelf struct {
+	ID      string `storm:"id"`
+	Owner   string `storm:"index"`
+	Name    string
+	Kind    ShelfKind
+	Hashes  []string
+	Created time.Time
+}
+
+// ReadingStatus is where a user is at in a book.
+type ReadingStatus string
+
+// Known reading statuses, surfaced as the "Currently Reading" and
+// "Finished" virtual shelves.
+const (
+	StatusUnread   ReadingStatus = "unread"
+	StatusReading  ReadingStatus = "reading"
+	StatusFinished ReadingStatus = "finished"
+)
+
+// ReadingState tracks one user's progress through one book.
+type ReadingState struct {
+	ID       string `storm:"id"` // Username + "/" + BookHash
+	Username string `storm:"index"`
+	BookHash string `storm:"index"`
+	Status   ReadingStatus
+	Progress float64 // 0-1
+	LastRead time.Time
+}
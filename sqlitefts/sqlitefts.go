@@ -0,0 +1,233 @@
+// Package sqlitefts implements the booksing search interface on top of a
+// SQLite FTS5 virtual table, so small self-hosted deployments don't need a
+// separate Meili instance.
+//
+// The fts5 module is an optional, compile-time feature of go-sqlite3: the
+// booksing binary must be built with `-tags sqlite_fts5` (e.g.
+// `go build -tags sqlite_fts5 ./...`) for New to work. Without that tag,
+// New fails at runtime with a wrapped "no such module: fts5" error as soon
+// as someone picks cfg.Database = "sqlite://...".
+package sqlitefts
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gnur/booksing"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+const schema = `
+CREATE VIRTUAL TABLE IF NOT EXISTS books USING fts5(
+	title,
+	author,
+	description,
+	hash UNINDEXED,
+	added UNINDEXED,
+	tokenize = 'porter unicode61 remove_diacritics 2'
+);
+`
+
+// SQLiteFTS stores and searches books in a SQLite FTS5 virtual table.
+type SQLiteFTS struct {
+	db        *sql.DB
+	batchSize int
+}
+
+// New opens (or creates) the FTS5 table at dsn. batchSize controls how many
+// books are written per transaction in AddBooks.
+//
+// This requires the running binary to have been built with
+// `-tags sqlite_fts5`; see the package doc comment.
+func New(dsn string, batchSize int) (*SQLiteFTS, error) {
+	db, err := sql.Open("sqlite3", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open sqlite database: %w", err)
+	}
+
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		if strings.Contains(err.Error(), "no such module: fts5") {
+			return nil, fmt.Errorf("unable to create fts5 table: %w (rebuild booksing with -tags sqlite_fts5)", err)
+		}
+		return nil, fmt.Errorf("unable to create fts5 table: %w", err)
+	}
+
+	if batchSize <= 0 {
+		batchSize = 50
+	}
+
+	return &SQLiteFTS{db: db, batchSize: batchSize}, nil
+}
+
+// Close releases the underlying SQLite connection.
+func (s *SQLiteFTS) Close() error {
+	return s.db.Close()
+}
+
+// AddBooks inserts or replaces books in batched transactions of batchSize.
+func (s *SQLiteFTS) AddBooks(books []booksing.Book, sync bool) error {
+	for start := 0; start < len(books); start += s.batchSize {
+		end := start + s.batchSize
+		if end > len(books) {
+			end = len(books)
+		}
+
+		if err := s.addBatch(books[start:end]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *SQLiteFTS) addBatch(books []booksing.Book) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("unable to start transaction: %w", err)
+	}
+
+	del, err := tx.Prepare(`DELETE FROM books WHERE hash = ?`)
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("unable to prepare delete statement: %w", err)
+	}
+	defer del.Close()
+
+	ins, err := tx.Prepare(`INSERT INTO books (title, author, description, hash, added) VALUES (?, ?, ?, ?, ?)`)
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("unable to prepare insert statement: %w", err)
+	}
+	defer ins.Close()
+
+	for _, b := range books {
+		if _, err := del.Exec(b.Hash); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("unable to delete existing book %s: %w", b.Hash, err)
+		}
+		if _, err := ins.Exec(b.Title, b.Author, b.Description, b.Hash, b.Added.Format(time.RFC3339)); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("unable to insert book %s: %w", b.Hash, err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetBook returns the book with the given hash, or sql.ErrNoRows if absent.
+func (s *SQLiteFTS) GetBook(hash string) (*booksing.Book, error) {
+	row := s.db.QueryRow(`SELECT title, author, description, hash, added FROM books WHERE hash = ?`, hash)
+
+	var b booksing.Book
+	var added string
+	if err := row.Scan(&b.Title, &b.Author, &b.Description, &b.Hash, &added); err != nil {
+		return nil, err
+	}
+	b.Added, _ = time.Parse(time.RFC3339, added)
+
+	return &b, nil
+}
+
+// DeleteBook removes the book with the given hash from the index.
+func (s *SQLiteFTS) DeleteBook(hash string) error {
+	_, err := s.db.Exec(`DELETE FROM books WHERE hash = ?`, hash)
+	if err != nil {
+		return fmt.Errorf("unable to delete book %s: %w", hash, err)
+	}
+	return nil
+}
+
+// GetBooksByHashes fetches a fixed set of books by id, e.g. the members
+// of a shelf, preserving SQL's usual LIMIT/OFFSET pagination.
+func (s *SQLiteFTS) GetBooksByHashes(hashes []string, limit, offset int64) ([]booksing.Book, error) {
+	if len(hashes) == 0 {
+		return nil, nil
+	}
+
+	placeholders := make([]byte, 0, len(hashes)*2)
+	args := make([]interface{}, 0, len(hashes)+2)
+	for i, h := range hashes {
+		if i > 0 {
+			placeholders = append(placeholders, ',')
+		}
+		placeholders = append(placeholders, '?')
+		args = append(args, h)
+	}
+	args = append(args, limit, offset)
+
+	query := fmt.Sprintf(
+		`SELECT title, author, description, hash, added FROM books WHERE hash IN (%s) ORDER BY added DESC LIMIT ? OFFSET ?`,
+		string(placeholders),
+	)
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("unable to query books by hash: %w", err)
+	}
+	defer rows.Close()
+
+	var books []booksing.Book
+	for rows.Next() {
+		var b booksing.Book
+		var added string
+		if err := rows.Scan(&b.Title, &b.Author, &b.Description, &b.Hash, &added); err != nil {
+			return nil, fmt.Errorf("unable to scan book row: %w", err)
+		}
+		b.Added, _ = time.Parse(time.RFC3339, added)
+		books = append(books, b)
+	}
+
+	return books, rows.Err()
+}
+
+// GetBooks searches the index with FTS5 MATCH syntax, so callers get
+// prefix queries ("term*"), phrase queries ("\"exact phrase\"") and
+// AND/OR/NOT operators for free. Results are ranked by bm25, best first.
+// An empty query falls back to the most recently added books.
+func (s *SQLiteFTS) GetBooks(q string, limit, offset int64) ([]booksing.Book, error) {
+	if q == "" {
+		return s.GetRecentBooks(limit, offset)
+	}
+
+	rows, err := s.db.Query(
+		`SELECT title, author, description, hash, added FROM books WHERE books MATCH ? ORDER BY bm25(books) LIMIT ? OFFSET ?`,
+		q, limit, offset,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("unable to query fts5 index: %w", err)
+	}
+	defer rows.Close()
+
+	return scanBooks(rows)
+}
+
+// GetRecentBooks returns books ordered by Added, newest first.
+func (s *SQLiteFTS) GetRecentBooks(limit, offset int64) ([]booksing.Book, error) {
+	rows, err := s.db.Query(
+		`SELECT title, author, description, hash, added FROM books ORDER BY added DESC LIMIT ? OFFSET ?`,
+		limit, offset,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("unable to query fts5 index: %w", err)
+	}
+	defer rows.Close()
+
+	return scanBooks(rows)
+}
+
+func scanBooks(rows *sql.Rows) ([]booksing.Book, error) {
+	var books []booksing.Book
+	for rows.Next() {
+		var b booksing.Book
+		var added string
+		if err := rows.Scan(&b.Title, &b.Author, &b.Description, &b.Hash, &added); err != nil {
+			return nil, fmt.Errorf("unable to scan book row: %w", err)
+		}
+		b.Added, _ = time.Parse(time.RFC3339, added)
+		books = append(books, b)
+	}
+
+	return books, rows.Err()
+}
@@ -0,0 +1,42 @@
+package storm
+
+import (
+	"fmt"
+
+	"github.com/gnur/booksing/activitypub"
+)
+
+// actorKeyID is the fixed storm key the actor's single keypair is stored
+// under; a booksing instance has exactly one ActivityPub actor.
+const actorKeyID = "activitypub-actor-key"
+
+type actorKeyRecord struct {
+	ID            string `storm:"id"`
+	PublicKeyPEM  string
+	PrivateKeyPEM string
+}
+
+// GetActorKeyPair returns the persisted ActivityPub actor keypair.
+func (d *DB) GetActorKeyPair() (*activitypub.KeyPair, error) {
+	var rec actorKeyRecord
+	if err := d.db.One("ID", actorKeyID, &rec); err != nil {
+		return nil, fmt.Errorf("unable to get actor keypair: %w", err)
+	}
+	return &activitypub.KeyPair{
+		PublicKeyPEM:  rec.PublicKeyPEM,
+		PrivateKeyPEM: rec.PrivateKeyPEM,
+	}, nil
+}
+
+// SaveActorKeyPair persists the ActivityPub actor keypair.
+func (d *DB) SaveActorKeyPair(kp *activitypub.KeyPair) error {
+	rec := actorKeyRecord{
+		ID:            actorKeyID,
+		PublicKeyPEM:  kp.PublicKeyPEM,
+		PrivateKeyPEM: kp.PrivateKeyPEM,
+	}
+	if err := d.db.Save(&rec); err != nil {
+		return fmt.Errorf("unable to save actor keypair: %w", err)
+	}
+	return nil
+}
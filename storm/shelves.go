@@ -0,0 +1,122 @@
+package storm
+
+import (
+	"errors"
+	"fmt"
+
+	stormdb "github.com/asdine/storm/v3"
+	"github.com/asdine/storm/v3/q"
+	"github.com/gnur/booksing"
+)
+
+// GetShelves returns every shelf owned by username, including the
+// built-in ones created for it on first use.
+func (d *DB) GetShelves(username string) ([]booksing.Shelf, error) {
+	var shelves []booksing.Shelf
+	err := d.db.Find("Owner", username, &shelves)
+	if err != nil && err != stormdb.ErrNotFound {
+		return nil, fmt.Errorf("unable to list shelves for %s: %w", username, err)
+	}
+	return shelves, nil
+}
+
+// GetShelf looks a shelf up by id. It returns booksing.ErrNotFound,
+// wrapped, if no such shelf exists.
+func (d *DB) GetShelf(id string) (*booksing.Shelf, error) {
+	var s booksing.Shelf
+	if err := d.db.One("ID", id, &s); err != nil {
+		if errors.Is(err, stormdb.ErrNotFound) {
+			return nil, fmt.Errorf("shelf %s: %w", id, booksing.ErrNotFound)
+		}
+		return nil, fmt.Errorf("unable to get shelf %s: %w", id, err)
+	}
+	return &s, nil
+}
+
+// SaveShelf creates or updates a shelf.
+func (d *DB) SaveShelf(s *booksing.Shelf) error {
+	if err := d.db.Save(s); err != nil {
+		return fmt.Errorf("unable to save shelf %s: %w", s.ID, err)
+	}
+	return nil
+}
+
+// DeleteShelf removes a shelf and its membership.
+func (d *DB) DeleteShelf(id string) error {
+	s, err := d.GetShelf(id)
+	if err != nil {
+		return err
+	}
+	if err := d.db.DeleteStruct(s); err != nil {
+		return fmt.Errorf("unable to delete shelf %s: %w", id, err)
+	}
+	return nil
+}
+
+// AddToShelf adds a book hash to a shelf, if it isn't already on it.
+func (d *DB) AddToShelf(id, hash string) error {
+	s, err := d.GetShelf(id)
+	if err != nil {
+		return err
+	}
+	for _, h := range s.Hashes {
+		if h == hash {
+			return nil
+		}
+	}
+	s.Hashes = append(s.Hashes, hash)
+	return d.SaveShelf(s)
+}
+
+// RemoveFromShelf removes a book hash from a shelf, if present.
+func (d *DB) RemoveFromShelf(id, hash string) error {
+	s, err := d.GetShelf(id)
+	if err != nil {
+		return err
+	}
+	hashes := s.Hashes[:0]
+	for _, h := range s.Hashes {
+		if h != hash {
+			hashes = append(hashes, h)
+		}
+	}
+	s.Hashes = hashes
+	return d.SaveShelf(s)
+}
+
+// GetReadingState returns a user's progress on a single book, or
+// stormdb.ErrNotFound if they haven't started it yet.
+func (d *DB) GetReadingState(username, hash string) (*booksing.ReadingState, error) {
+	var rs booksing.ReadingState
+	if err := d.db.One("ID", readingStateID(username, hash), &rs); err != nil {
+		return nil, err
+	}
+	return &rs, nil
+}
+
+// SaveReadingState creates or updates a user's progress on a book.
+func (d *DB) SaveReadingState(rs *booksing.ReadingState) error {
+	rs.ID = readingStateID(rs.Username, rs.BookHash)
+	if err := d.db.Save(rs); err != nil {
+		return fmt.Errorf("unable to save reading state %s: %w", rs.ID, err)
+	}
+	return nil
+}
+
+// GetReadingStates returns every book a user has in the given status, for
+// the "Currently Reading" and "Finished" virtual shelves.
+func (d *DB) GetReadingStates(username string, status booksing.ReadingStatus) ([]booksing.ReadingState, error) {
+	var states []booksing.ReadingState
+	err := d.db.Select(
+		q.Eq("Username", username),
+		q.Eq("Status", status),
+	).Find(&states)
+	if err != nil && err != stormdb.ErrNotFound {
+		return nil, fmt.Errorf("unable to list reading states for %s: %w", username, err)
+	}
+	return states, nil
+}
+
+func readingStateID(username, hash string) string {
+	return username + "/" + hash
+}
@@ -0,0 +1,47 @@
+// Package storm implements booksing's database interface on top of a
+// BoltDB-backed object store.
+package storm
+
+import (
+	"fmt"
+
+	stormdb "github.com/asdine/storm/v3"
+	"github.com/gnur/booksing"
+)
+
+// DB persists booksing's users, downloads and shelves in a local BoltDB file.
+type DB struct {
+	db *stormdb.DB
+}
+
+// New opens (or creates) the BoltDB file at path.
+func New(path string) (*DB, error) {
+	db, err := stormdb.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open storm database: %w", err)
+	}
+
+	return &DB{db: db}, nil
+}
+
+// Close releases the underlying BoltDB file handle.
+func (d *DB) Close() error {
+	return d.db.Close()
+}
+
+// GetUser looks a user up by username.
+func (d *DB) GetUser(username string) (*booksing.User, error) {
+	var u booksing.User
+	if err := d.db.One("Username", username, &u); err != nil {
+		return nil, fmt.Errorf("unable to get user %s: %w", username, err)
+	}
+	return &u, nil
+}
+
+// SaveUser creates or updates a user.
+func (d *DB) SaveUser(u *booksing.User) error {
+	if err := d.db.Save(u); err != nil {
+		return fmt.Errorf("unable to save user %s: %w", u.Username, err)
+	}
+	return nil
+}
@@ -0,0 +1,15 @@
+package booksing
+
+// User is a booksing account. Username is its natural key, used as the
+// session identity and storm's id field.
+type User struct {
+	Username string `storm:"id"`
+	IsAdmin  bool
+	// Token authenticates headless clients (e.g. OPDS readers) over HTTP
+	// Basic Auth, as an alternative to the browser session cookie.
+	Token string
+	// PasswordHash holds an argon2id-encoded password, in the format
+	// $argon2id$v=19$m=...,t=...,p=...$<b64salt>$<b64hash>. Empty means the
+	// user has no password set and must sign in via Google OAuth.
+	PasswordHash string
+}